@@ -0,0 +1,321 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultRetry is the reconnection delay a Client uses before it has seen
+// a `retry` field on the stream. Once a retry field has been seen, its
+// value stays in effect across reconnects until another one replaces it.
+const DefaultRetry = 3 * time.Second
+
+// DefaultMaxRetry is the upper bound a Client's exponential backoff is
+// capped at when ClientOptionMaxRetry is not supplied.
+const DefaultMaxRetry = 30 * time.Second
+
+// ClientOption configures a Client constructed via NewClient.
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	httpClient *http.Client
+	headers    http.Header
+	ctx        context.Context
+	retry      time.Duration
+	maxRetry   time.Duration
+	onRetry    func(attempt int, err error, delay time.Duration)
+}
+
+// ClientOptionHTTPClient overrides the *http.Client used to issue
+// requests. The default is http.DefaultClient.
+func ClientOptionHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *clientConfig) {
+		c.httpClient = httpClient
+	}
+}
+
+// ClientOptionHeader adds an extra header to every request the Client
+// issues, including reconnects. It may be called more than once to add
+// multiple headers, or multiple values for the same header.
+func ClientOptionHeader(key, value string) ClientOption {
+	return func(c *clientConfig) {
+		c.headers.Add(key, value)
+	}
+}
+
+// ClientOptionContext bounds the Client's lifetime: when ctx is done, the
+// Client stops reconnecting, closes its event channel, and any
+// in-progress request is canceled. The default is context.Background(),
+// meaning the Client runs until Close is called.
+func ClientOptionContext(ctx context.Context) ClientOption {
+	return func(c *clientConfig) {
+		c.ctx = ctx
+	}
+}
+
+// ClientOptionInitialRetry sets the reconnection delay used before any
+// `retry` field has been seen on the stream, and the base that
+// exponential backoff grows from on repeated failures. The default is
+// DefaultRetry.
+func ClientOptionInitialRetry(retry time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.retry = retry
+	}
+}
+
+// ClientOptionMaxRetry caps the exponential backoff applied between
+// repeated connection failures. The default is DefaultMaxRetry.
+func ClientOptionMaxRetry(maxRetry time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.maxRetry = maxRetry
+	}
+}
+
+// ClientOptionOnRetry registers a hook invoked every time the Client is
+// about to sleep before a reconnect attempt, with the attempt number
+// (starting at 1), the error that triggered the reconnect (nil if the
+// stream simply ended), and the delay about to be applied. It is called
+// from the Client's background goroutine and should return quickly.
+func ClientOptionOnRetry(onRetry func(attempt int, err error, delay time.Duration)) ClientOption {
+	return func(c *clientConfig) {
+		c.onRetry = onRetry
+	}
+}
+
+// Client connects to a server-sent events endpoint and delivers the
+// events it receives on a channel, reconnecting automatically per the
+// SSE spec on transport errors or server-side stream closure. It mirrors
+// the reconnection behavior of the browser EventSource API.
+type Client struct {
+	url string
+	cfg clientConfig
+
+	events chan Event
+
+	// ctx is a child of cfg.ctx that Close cancels directly. Using it for
+	// every outgoing request and every decoder call means canceling it
+	// aborts an in-progress request immediately, rather than leaving
+	// Close to wait on a connect/read that nothing else will interrupt.
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewClient returns a Client that issues GET requests to url and starts
+// connecting immediately in the background.
+func NewClient(url string, opts ...ClientOption) *Client {
+	cfg := clientConfig{
+		httpClient: http.DefaultClient,
+		headers:    make(http.Header),
+		ctx:        context.Background(),
+		retry:      DefaultRetry,
+		maxRetry:   DefaultMaxRetry,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ctx, cancel := context.WithCancel(cfg.ctx)
+
+	c := &Client{
+		url:    url,
+		cfg:    cfg,
+		events: make(chan Event),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go c.run()
+
+	return c
+}
+
+// Events returns the channel events are delivered on. It is closed when
+// the Client gives up permanently (HTTP 204, or a non-2xx response other
+// than 500/502/503/504) or is shut down via Close or its context.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// Close stops the Client: any in-progress request is abandoned, no
+// further reconnect attempts are made, and Events() is closed. Close is
+// idempotent and blocks until the background goroutine has exited.
+func (c *Client) Close() error {
+	c.closeOnce.Do(c.cancel)
+	<-c.done
+	return nil
+}
+
+// run drives the connect/stream/reconnect loop until the Client is
+// closed, its context is done, or it gives up permanently.
+func (c *Client) run() {
+	defer close(c.done)
+	defer close(c.events)
+
+	lastEventID := ""
+	retry := c.cfg.retry
+	attempt := 0
+
+	for {
+		if c.stopped() {
+			return
+		}
+
+		resp, err := c.connect(lastEventID)
+		if err != nil {
+			attempt++
+			delay := backoff(retry, c.cfg.maxRetry, attempt)
+			c.notifyRetry(attempt, err, delay)
+			if !c.sleep(delay) {
+				return
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusNoContent {
+			resp.Body.Close()
+			return
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			resp.Body.Close()
+			if !isRetryableStatus(resp.StatusCode) {
+				return
+			}
+			attempt++
+			delay := backoff(retry, c.cfg.maxRetry, attempt)
+			c.notifyRetry(attempt, fmt.Errorf("eventsource: unexpected status %d", resp.StatusCode), delay)
+			if !c.sleep(delay) {
+				return
+			}
+			continue
+		}
+
+		attempt = 0
+		lastEventID, retry = c.stream(resp, lastEventID, retry)
+
+		if c.stopped() {
+			return
+		}
+		c.notifyRetry(1, nil, retry)
+		if !c.sleep(retry) {
+			return
+		}
+	}
+}
+
+// stream decodes events from resp.Body until the stream ends or the
+// Client is stopped, forwarding each one to c.events. It returns the
+// last-event-ID and retry delay in effect when the stream ended, so the
+// next connection attempt can pick them up.
+func (c *Client) stream(resp *http.Response, lastEventID string, retry time.Duration) (string, time.Duration) {
+	defer resp.Body.Close()
+
+	decoder := NewDecoderWithOptions(resp.Body, DecoderOptionLastEventID(lastEventID))
+	defer decoder.Close()
+
+	for {
+		event, err := decoder.DecodeContext(c.ctx)
+		if err != nil {
+			return lastEventID, retry
+		}
+
+		if withID, ok := event.(EventWithLastID); ok {
+			lastEventID = withID.LastEventID()
+		}
+		if withRetry, ok := event.(EventWithRetry); ok {
+			if ms := withRetry.RetryMS(); ms > 0 {
+				retry = ms
+			}
+		}
+
+		select {
+		case c.events <- event:
+		case <-c.ctx.Done():
+			return lastEventID, retry
+		}
+	}
+}
+
+// connect issues a single GET request for the event stream.
+func (c *Client) connect(lastEventID string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	for key, values := range c.cfg.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	return c.cfg.httpClient.Do(req)
+}
+
+func (c *Client) notifyRetry(attempt int, err error, delay time.Duration) {
+	if c.cfg.onRetry != nil {
+		c.cfg.onRetry(attempt, err, delay)
+	}
+}
+
+// sleep waits for d, or returns false early if the Client is stopped.
+func (c *Client) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+func (c *Client) stopped() bool {
+	select {
+	case <-c.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus reports whether a non-2xx status is one the browser
+// EventSource spec treats as transient, worth reconnecting for, as
+// opposed to a permanent failure.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff computes the delay before the given reconnect attempt (1-based),
+// doubling base each attempt, capped at max, with up to 50% jitter.
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	if d <= 0 {
+		return 0
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
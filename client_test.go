@@ -0,0 +1,239 @@
+package eventsource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustReceiveEvent(t *testing.T, c *Client, timeout time.Duration) Event {
+	t.Helper()
+	select {
+	case event, ok := <-c.Events():
+		require.True(t, ok, "Events channel closed unexpectedly")
+		return event
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestClientReceivesEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: hello\n\n")
+		fmt.Fprint(w, "id: 1\ndata: world\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	defer client.Close()
+
+	event1 := mustReceiveEvent(t, client, time.Second)
+	assert.Equal(t, "hello", event1.Data())
+
+	event2 := mustReceiveEvent(t, client, time.Second)
+	assert.Equal(t, "world", event2.Data())
+	assert.Equal(t, "1", event2.Id())
+}
+
+func TestClientSetsStandardHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: hello\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, ClientOptionHeader("X-Custom", "value"))
+	defer client.Close()
+
+	mustReceiveEvent(t, client, time.Second)
+
+	select {
+	case h := <-headers:
+		assert.Equal(t, "text/event-stream", h.Get("Accept"))
+		assert.Equal(t, "no-cache", h.Get("Cache-Control"))
+		assert.Equal(t, "value", h.Get("X-Custom"))
+		assert.Equal(t, "", h.Get("Last-Event-ID"))
+	case <-time.After(time.Second):
+		t.Fatal("request never reached server")
+	}
+}
+
+func TestClientSendsLastEventIDOnReconnect(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if atomic.AddInt32(&requestCount, 1) == 1 {
+			fmt.Fprint(w, "id: abc\ndata: first\n\n")
+			return
+		}
+		assert.Equal(t, "abc", r.Header.Get("Last-Event-ID"))
+		fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, ClientOptionInitialRetry(5*time.Millisecond))
+	defer client.Close()
+
+	event1 := mustReceiveEvent(t, client, time.Second)
+	assert.Equal(t, "first", event1.Data())
+
+	event2 := mustReceiveEvent(t, client, time.Second)
+	assert.Equal(t, "second", event2.Data())
+}
+
+func TestClientGivesUpOn204(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, ClientOptionInitialRetry(5*time.Millisecond))
+	defer client.Close()
+
+	select {
+	case _, ok := <-client.Events():
+		assert.False(t, ok, "Events channel should be closed after a 204 response")
+	case <-time.After(time.Second):
+		t.Fatal("Client did not give up after a 204 response")
+	}
+}
+
+func TestClientGivesUpOnNonRetryableStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, ClientOptionInitialRetry(5*time.Millisecond))
+	defer client.Close()
+
+	select {
+	case _, ok := <-client.Events():
+		assert.False(t, ok, "Events channel should be closed after a non-retryable status")
+	case <-time.After(time.Second):
+		t.Fatal("Client did not give up after a 404 response")
+	}
+}
+
+func TestClientRetriesOnRetryableStatus(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requestCount, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: recovered\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, ClientOptionInitialRetry(5*time.Millisecond))
+	defer client.Close()
+
+	event := mustReceiveEvent(t, client, time.Second)
+	assert.Equal(t, "recovered", event.Data())
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&requestCount), int32(2))
+}
+
+func TestClientOnRetryHookObservesAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	attempts := make(chan int, 10)
+	client := NewClient(server.URL,
+		ClientOptionInitialRetry(5*time.Millisecond),
+		ClientOptionMaxRetry(10*time.Millisecond),
+		ClientOptionOnRetry(func(attempt int, err error, delay time.Duration) {
+			require.Error(t, err)
+			attempts <- attempt
+		}),
+	)
+	defer client.Close()
+
+	select {
+	case attempt := <-attempts:
+		assert.Equal(t, 1, attempt)
+	case <-time.After(time.Second):
+		t.Fatal("onRetry hook was never invoked")
+	}
+}
+
+func TestClientStopsOnContextCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewClient(server.URL, ClientOptionContext(ctx))
+
+	mustReceiveEvent(t, client, time.Second)
+	cancel()
+
+	select {
+	case _, ok := <-client.Events():
+		assert.False(t, ok, "Events channel should close once the context is canceled")
+	case <-time.After(time.Second):
+		t.Fatal("Client did not stop after context cancellation")
+	}
+}
+
+func TestCloseAbortsHungConnect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Simulates a server that accepts the connection but never
+		// replies; the handler only returns once the client gives up.
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	// Give the background goroutine time to reach httpClient.Do before
+	// closing, so Close races with an in-progress, never-responding
+	// request rather than with a request that has not been sent yet.
+	time.Sleep(20 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		client.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return while a connect was hung waiting on the server")
+	}
+}
+
+func TestClientCloseIsIdempotent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: hello\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	mustReceiveEvent(t, client, time.Second)
+
+	assert.NotPanics(t, func() {
+		client.Close()
+		client.Close()
+	})
+}
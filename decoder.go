@@ -0,0 +1,298 @@
+package eventsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DecoderOption configures a Decoder constructed via NewDecoderWithOptions.
+type DecoderOption func(*decoderConfig)
+
+type decoderConfig struct {
+	lastEventID     string
+	shutdownTimeout time.Duration
+}
+
+// DefaultShutdownTimeout is the shutdown timeout used when
+// DecoderOptionShutdownTimeout is not supplied.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// DecoderOptionLastEventID seeds the decoder with a Last-Event-ID value,
+// as if it had been set by a previous event on the stream. It will be
+// reported by EventWithLastID.LastEventID() until an event carrying its
+// own `id` field overrides it.
+func DecoderOptionLastEventID(id string) DecoderOption {
+	return func(c *decoderConfig) {
+		c.lastEventID = id
+	}
+}
+
+// DecoderOptionShutdownTimeout bounds how long Close will block waiting
+// for the background scan goroutine to exit. The scan goroutine can only
+// exit once its current read on the underlying io.Reader returns, so
+// callers that want Close to return promptly should also arrange for
+// that reader (e.g. the body of an in-flight HTTP response) to be closed
+// or to have its deadline expire.
+func DecoderOptionShutdownTimeout(timeout time.Duration) DecoderOption {
+	return func(c *decoderConfig) {
+		c.shutdownTimeout = timeout
+	}
+}
+
+// Decoder reads a stream of server-sent events from an io.Reader.
+//
+// A Decoder starts a single background goroutine that scans the
+// underlying reader and parses events from it; Decode and DecodeContext
+// receive events from that goroutine over a channel. Close is idempotent
+// and safe to call concurrently with a pending Decode or DecodeContext
+// call: every such call observes io.EOF (or ctx.Err(), for
+// DecodeContext) rather than a panic or a hang.
+type Decoder struct {
+	events chan Event
+	errs   chan error
+
+	closeOnce       sync.Once
+	closeCh         chan struct{}
+	done            chan struct{}
+	shutdownTimeout time.Duration
+}
+
+// NewDecoder returns a Decoder that reads events from r using default
+// options.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r)
+}
+
+// NewDecoderWithOptions returns a Decoder that reads events from r,
+// configured by opts.
+func NewDecoderWithOptions(r io.Reader, opts ...DecoderOption) *Decoder {
+	cfg := decoderConfig{shutdownTimeout: DefaultShutdownTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	d := &Decoder{
+		events:          make(chan Event),
+		errs:            make(chan error, 1),
+		closeCh:         make(chan struct{}),
+		done:            make(chan struct{}),
+		shutdownTimeout: cfg.shutdownTimeout,
+	}
+
+	go d.scan(r, cfg.lastEventID)
+
+	return d
+}
+
+// Decode blocks until the next event is parsed from the stream, the
+// stream ends (io.EOF), an error occurs, or the Decoder is closed (also
+// io.EOF). It is equivalent to DecodeContext(context.Background()).
+func (d *Decoder) Decode() (Event, error) {
+	return d.DecodeContext(context.Background())
+}
+
+// DecodeContext behaves like Decode, but also returns ctx.Err() promptly
+// if ctx is canceled or its deadline elapses before an event arrives.
+// Returning early this way does not stop or leak the background scan
+// goroutine: it keeps running and will simply deliver (or discard, if
+// the Decoder is later closed) whatever it reads next.
+func (d *Decoder) DecodeContext(ctx context.Context) (Event, error) {
+	select {
+	case event := <-d.events:
+		return event, nil
+	case err := <-d.errs:
+		return nil, err
+	case <-d.closeCh:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close shuts the Decoder down: any Decode or DecodeContext call already
+// in progress, or started afterwards, returns io.EOF. Close is idempotent
+// and may be called concurrently with Decode/DecodeContext from other
+// goroutines. It blocks until the background scan goroutine exits or
+// the configured shutdown timeout elapses, whichever comes first.
+func (d *Decoder) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.closeCh)
+	})
+
+	select {
+	case <-d.done:
+	case <-time.After(d.shutdownTimeout):
+	}
+
+	return nil
+}
+
+// utf8BOM is the byte sequence a conforming stream may send exactly once,
+// before anything else, to mark its encoding.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// scan reads lines from r, assembles them into events per the WHATWG
+// event-stream grammar, and delivers each one on d.events. It runs until
+// r is exhausted, a read error occurs, or d.closeCh is closed, then
+// closes d.done.
+func (d *Decoder) scan(r io.Reader, initialLastEventID string) {
+	defer close(d.done)
+
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Split(scanLines)
+
+	lastEventID := initialLastEventID
+	var retryMS time.Duration
+	var dataLines []string
+	eventType := ""
+	id := ""
+
+	dispatch := func() {
+		defer func() {
+			eventType = ""
+			id = ""
+			dataLines = nil
+		}()
+
+		// Per spec, an event with no data field at all is discarded rather
+		// than dispatched with empty data; a data field with an empty
+		// value still contributes a (blank) line to the buffer, so it is
+		// dispatched with Data() == "".
+		if len(dataLines) == 0 {
+			return
+		}
+
+		pub := &publication{
+			id:          id,
+			event:       eventType,
+			data:        strings.Join(dataLines, "\n"),
+			lastEventID: lastEventID,
+			retryMS:     retryMS,
+		}
+		select {
+		case d.events <- pub:
+		case <-d.closeCh:
+		}
+	}
+
+	for scanner.Scan() {
+		select {
+		case <-d.closeCh:
+			return
+		default:
+		}
+
+		line := scanner.Text()
+		if line == "" {
+			dispatch()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment line; ignored per spec.
+			continue
+		}
+
+		field, value := splitField(line)
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			dataLines = append(dataLines, value)
+		case "id":
+			// An id field containing a NUL byte is ignored per spec.
+			if strings.IndexByte(value, 0) >= 0 {
+				continue
+			}
+			id = value
+			lastEventID = value
+		case "retry":
+			if ms, ok := parseRetry(value); ok {
+				retryMS = time.Duration(ms) * time.Millisecond
+			}
+			// Non-numeric retry values are silently ignored per spec.
+		}
+		// Unrecognized field names are silently ignored per spec.
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	select {
+	case d.errs <- err:
+	case <-d.closeCh:
+	}
+}
+
+// splitField splits a field line on its first colon, stripping a single
+// leading space from the value as required by the event-stream grammar.
+func splitField(line string) (field, value string) {
+	idx := strings.IndexByte(line, ':')
+	if idx < 0 {
+		return line, ""
+	}
+	field = line[:idx]
+	value = strings.TrimPrefix(line[idx+1:], " ")
+	return field, value
+}
+
+// parseRetry parses the value of a retry field, which per spec must
+// consist solely of ASCII digits (unlike strconv.ParseInt, which also
+// accepts a leading sign). It returns ok == false for anything else,
+// including an empty value.
+func parseRetry(value string) (ms int64, ok bool) {
+	if value == "" {
+		return 0, false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return 0, false
+		}
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	return ms, err == nil
+}
+
+// scanLines is a bufio.SplitFunc that treats "\n", "\r", and "\r\n" all as
+// line terminators, per the event-stream grammar (the default
+// bufio.ScanLines only recognizes "\n", treating a bare "\r" as ordinary
+// data).
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// data[i] == '\r': need to know whether it's followed by '\n'.
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
@@ -1,6 +1,7 @@
 package eventsource
 
 import (
+	"context"
 	"io"
 	"runtime"
 	"strings"
@@ -21,9 +22,11 @@ func TestDecode(t *testing.T) {
 			wantedEvents: []*publication{{event: "eventName", data: "{\"sample\":\"value\"}"}},
 		},
 		{
-			// the newlines should not be parsed as empty event
-			rawInput:     "\n\n\nevent: event1\n\n\n\n\nevent: event2\n\n",
-			wantedEvents: []*publication{{event: "event1"}, {event: "event2"}},
+			// the newlines should not be parsed as empty event, and an event
+			// with no data field at all is dropped rather than dispatched empty,
+			// per spec
+			rawInput:     "\n\n\nevent: event1\ndata: d1\n\n\n\n\nevent: event2\ndata: d2\n\n",
+			wantedEvents: []*publication{{event: "event1", data: "d1"}, {event: "event2", data: "d2"}},
 		},
 		{
 			rawInput:     "id: abc\ndata: def\n\n",
@@ -34,6 +37,39 @@ func TestDecode(t *testing.T) {
 			rawInput:     "id: a\x00bc\ndata: def\n\n",
 			wantedEvents: []*publication{{data: "def"}},
 		},
+		{
+			// multiple data fields are concatenated with "\n" between them
+			rawInput:     "data: line one\ndata: line two\ndata:line three\n\n",
+			wantedEvents: []*publication{{data: "line one\nline two\nline three"}},
+		},
+		{
+			// a single empty data field still contributes a (blank) line
+			// to the data buffer, so the event is dispatched with
+			// Data() == "", unlike an event with no data field at all
+			rawInput:     "event: noData\ndata:\n\ndata: abc\n\n",
+			wantedEvents: []*publication{{event: "noData", data: ""}, {data: "abc"}},
+		},
+		{
+			// two empty data fields are joined by the separating "\n",
+			// which is enough to make the data buffer non-empty
+			rawInput:     "data:\ndata:\n\n",
+			wantedEvents: []*publication{{data: "\n"}},
+		},
+		{
+			// comment lines and unrecognized field names are ignored
+			rawInput:     ": this is a comment\nfoo: bar\ndata: def\n\n",
+			wantedEvents: []*publication{{data: "def"}},
+		},
+		{
+			// CRLF and bare CR are both valid line terminators
+			rawInput:     "event: eventName\r\ndata: abc\r\n\r\ndata: def\r\r",
+			wantedEvents: []*publication{{event: "eventName", data: "abc"}, {data: "def"}},
+		},
+		{
+			// a leading UTF-8 BOM is stripped exactly once
+			rawInput:     "\xEF\xBB\xBFdata: def\n\n",
+			wantedEvents: []*publication{{data: "def"}},
+		},
 	}
 
 	for _, test := range tests {
@@ -59,6 +95,67 @@ func requireLastEventID(t *testing.T, event Event) string {
 	return eventWithID.LastEventID()
 }
 
+func requireRetryMS(t *testing.T, event Event) time.Duration {
+	// necessary because we can't yet add RetryMS to the basic Event interface; see EventWithRetry
+	eventWithRetry, ok := event.(EventWithRetry)
+	require.True(t, ok, "event should have implemented EventWithRetry")
+	return eventWithRetry.RetryMS()
+}
+
+func TestDecoderTracksRetry(t *testing.T) {
+	t.Run("retry is zero until a retry field is seen", func(t *testing.T) {
+		decoder := NewDecoder(strings.NewReader("data: abc\n\n"))
+
+		event, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), requireRetryMS(t, event))
+	})
+
+	t.Run("retry field sets the reconnection time in milliseconds", func(t *testing.T) {
+		inputData := "retry: 2500\ndata: abc\n\n"
+		decoder := NewDecoder(strings.NewReader(inputData))
+
+		event, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, 2500*time.Millisecond, requireRetryMS(t, event))
+	})
+
+	t.Run("retry persists across events until overridden", func(t *testing.T) {
+		inputData := "retry: 1000\ndata: first\n\ndata: second\n\nretry: 2000\ndata: third\n\n"
+		decoder := NewDecoder(strings.NewReader(inputData))
+
+		event1, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, 1000*time.Millisecond, requireRetryMS(t, event1))
+
+		event2, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, 1000*time.Millisecond, requireRetryMS(t, event2))
+
+		event3, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, 2000*time.Millisecond, requireRetryMS(t, event3))
+	})
+
+	t.Run("non-numeric retry values are silently ignored", func(t *testing.T) {
+		inputData := "retry: soon\ndata: abc\n\n"
+		decoder := NewDecoder(strings.NewReader(inputData))
+
+		event, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), requireRetryMS(t, event))
+	})
+
+	t.Run("signed retry values are silently ignored, per spec digits-only", func(t *testing.T) {
+		inputData := "retry: -1\ndata: abc\n\n"
+		decoder := NewDecoder(strings.NewReader(inputData))
+
+		event, err := decoder.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), requireRetryMS(t, event))
+	})
+}
+
 func TestDecoderTracksLastEventID(t *testing.T) {
 	t.Run("uses last ID that is passed in options", func(t *testing.T) {
 		inputData := "data: abc\n\n"
@@ -186,3 +283,86 @@ func TestEOFOnClose(t *testing.T) {
 	t.Log(runtime.NumGoroutine(), num)
 	// t.Log(decoder.Decode())
 }
+
+func TestDecodeContextReturnsErrOnCancel(t *testing.T) {
+	reader, writer := io.Pipe()
+	decoder := NewDecoder(reader)
+	defer decoder.Close()
+	defer writer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := decoder.DecodeContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestDecodeContextReturnsErrOnDeadlineExceeded(t *testing.T) {
+	reader, writer := io.Pipe()
+	decoder := NewDecoder(reader)
+	defer decoder.Close()
+	defer writer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := decoder.DecodeContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestDecodeContextStillDeliversEvents(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("data: test\n\n"))
+	defer decoder.Close()
+
+	event, err := decoder.DecodeContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "test", event.Data())
+}
+
+func TestCloseIsIdempotent(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("data: test\n\n"))
+
+	assert.NotPanics(t, func() {
+		decoder.Close()
+		decoder.Close()
+		decoder.Close()
+	})
+}
+
+func TestCloseConcurrentWithPendingDecode(t *testing.T) {
+	reader, writer := io.Pipe()
+	decoder := NewDecoderWithOptions(reader, DecoderOptionShutdownTimeout(50*time.Millisecond))
+
+	results := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := decoder.Decode()
+			results <- err
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.NotPanics(t, func() {
+		decoder.Close()
+	})
+	writer.Close()
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, io.EOF, <-results)
+	}
+}
+
+func TestCloseRespectsShutdownTimeout(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	decoder := NewDecoderWithOptions(reader, DecoderOptionShutdownTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	decoder.Close()
+	elapsed := time.Since(start)
+
+	// The scan goroutine is still blocked reading from the pipe, so Close
+	// must give up after roughly the configured timeout rather than
+	// hanging until the pipe is closed.
+	assert.Less(t, elapsed, 500*time.Millisecond)
+}
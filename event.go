@@ -0,0 +1,52 @@
+package eventsource
+
+import "time"
+
+// Event represents a single server-sent event as delivered by a Decoder.
+type Event interface {
+	// Id is the event's `id` field, or the empty string if none was sent.
+	Id() string
+	// Event is the event's `event` field, or the empty string if none was sent.
+	Event() string
+	// Data is the event's `data` field, with a trailing newline (if any) removed.
+	Data() string
+}
+
+// EventWithLastID is implemented by events produced by this package's
+// Decoder. It exposes the Last-Event-ID value in effect when the event was
+// received, which may come from an earlier event in the stream or from
+// DecoderOptionLastEventID rather than the event itself.
+type EventWithLastID interface {
+	Event
+	// LastEventID is the most recent `id` field seen on the stream so
+	// far, including this event's own id if it set one. An empty `id`
+	// field resets it to the empty string.
+	LastEventID() string
+}
+
+// EventWithRetry is implemented by events produced by this package's
+// Decoder. It exposes the reconnection time requested by the most recent
+// `retry` field seen on the stream so far, or zero if none has been sent.
+type EventWithRetry interface {
+	Event
+	// RetryMS is the reconnection time requested by the stream, as set by
+	// the most recent `retry` field seen so far (not necessarily on this
+	// event), or zero if the stream has never sent one.
+	RetryMS() time.Duration
+}
+
+// publication is the Decoder's implementation of Event, EventWithLastID
+// and EventWithRetry.
+type publication struct {
+	id          string
+	event       string
+	data        string
+	lastEventID string
+	retryMS     time.Duration
+}
+
+func (p *publication) Id() string             { return p.id }
+func (p *publication) Event() string          { return p.event }
+func (p *publication) Data() string           { return p.data }
+func (p *publication) LastEventID() string    { return p.lastEventID }
+func (p *publication) RetryMS() time.Duration { return p.retryMS }
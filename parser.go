@@ -0,0 +1,173 @@
+package eventsource
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// Parser is a synchronous, zero-goroutine alternative to Decoder. It
+// implements io.Writer so a caller that already has bytes in hand — from
+// an http.Response.Body, a bufio.Reader, a netpoll buffer — can feed them
+// directly, without spinning up a goroutine and channel per stream the
+// way Decoder does. This matters for servers that fan out thousands of
+// SSE streams at once, where one goroutine per stream is significant
+// overhead.
+//
+// Parser dispatches events inline, synchronously within the Write call
+// that completes them, by invoking the callback registered with OnEvent.
+// It tracks the last-event-ID and retry fields exactly as
+// NewDecoderWithOptions does.
+//
+// A Parser is not safe for concurrent use: a caller must serialize its
+// Write calls the same way it would for any other io.Writer backed by a
+// single connection.
+type Parser struct {
+	onEvent func(Event)
+
+	lastEventID string
+	retryMS     time.Duration
+	dataLines   []string
+	eventType   string
+	id          string
+
+	line       []byte
+	sawCR      bool
+	bomChecked bool
+	bomPending []byte
+}
+
+// NewParser returns a Parser seeded with lastEventID, as if it had been
+// set by a previous event on the stream (see DecoderOptionLastEventID).
+// Pass "" if the stream has no prior history.
+func NewParser(lastEventID string) *Parser {
+	return &Parser{lastEventID: lastEventID}
+}
+
+// OnEvent registers the callback invoked for each event as soon as its
+// terminating blank line is written. It replaces any previously
+// registered callback. Calling Write before OnEvent is set silently
+// drops any events parsed in the meantime.
+func (p *Parser) OnEvent(onEvent func(Event)) {
+	p.onEvent = onEvent
+}
+
+// Write implements io.Writer: it feeds b into the parser, dispatching
+// any events b completes to the OnEvent callback before returning. It
+// always consumes all of b and never returns an error.
+func (p *Parser) Write(b []byte) (int, error) {
+	n := len(b)
+
+	if !p.bomChecked {
+		b = p.stripBOM(b)
+	}
+
+	for len(b) > 0 {
+		if p.sawCR {
+			p.sawCR = false
+			if b[0] == '\n' {
+				b = b[1:]
+				continue
+			}
+		}
+
+		idx := bytes.IndexAny(b, "\r\n")
+		if idx < 0 {
+			p.line = append(p.line, b...)
+			break
+		}
+
+		p.line = append(p.line, b[:idx]...)
+		p.processLine(string(p.line))
+		p.line = p.line[:0]
+
+		if b[idx] == '\r' {
+			p.sawCR = true
+		}
+		b = b[idx+1:]
+	}
+
+	return n, nil
+}
+
+// stripBOM removes a leading UTF-8 BOM from the stream exactly once,
+// even if it arrives split across several Write calls (a single Write
+// may hand us as little as one byte of it). Bytes held back while still
+// waiting to see whether they form a BOM are buffered in p.bomPending
+// until there are enough of them to decide either way.
+func (p *Parser) stripBOM(b []byte) []byte {
+	p.bomPending = append(p.bomPending, b...)
+
+	if len(p.bomPending) < len(utf8BOM) {
+		if bytes.HasPrefix(utf8BOM, p.bomPending) {
+			// Still a possible BOM prefix; hold everything back until
+			// more bytes arrive to decide.
+			return nil
+		}
+		// Can never become a BOM; stop buffering and release it as-is.
+	}
+
+	p.bomChecked = true
+	pending := p.bomPending
+	p.bomPending = nil
+	if bytes.HasPrefix(pending, utf8BOM) {
+		return pending[len(utf8BOM):]
+	}
+	return pending
+}
+
+func (p *Parser) processLine(line string) {
+	if line == "" {
+		p.dispatch()
+		return
+	}
+	if strings.HasPrefix(line, ":") {
+		// Comment line; ignored per spec.
+		return
+	}
+
+	field, value := splitField(line)
+	switch field {
+	case "event":
+		p.eventType = value
+	case "data":
+		p.dataLines = append(p.dataLines, value)
+	case "id":
+		// An id field containing a NUL byte is ignored per spec.
+		if strings.IndexByte(value, 0) >= 0 {
+			return
+		}
+		p.id = value
+		p.lastEventID = value
+	case "retry":
+		if ms, ok := parseRetry(value); ok {
+			p.retryMS = time.Duration(ms) * time.Millisecond
+		}
+		// Non-numeric retry values are silently ignored per spec.
+	}
+	// Unrecognized field names are silently ignored per spec.
+}
+
+func (p *Parser) dispatch() {
+	defer func() {
+		p.eventType = ""
+		p.id = ""
+		p.dataLines = nil
+	}()
+
+	// Per spec, an event with no data field at all is discarded rather
+	// than dispatched with empty data; a data field with an empty value
+	// still contributes a (blank) line to the buffer, so it is dispatched
+	// with Data() == "".
+	if len(p.dataLines) == 0 || p.onEvent == nil {
+		return
+	}
+
+	p.onEvent(&publication{
+		id:          p.id,
+		event:       p.eventType,
+		data:        strings.Join(p.dataLines, "\n"),
+		lastEventID: p.lastEventID,
+		retryMS:     p.retryMS,
+	})
+}
@@ -0,0 +1,204 @@
+package eventsource
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParserDispatchesEvents(t *testing.T) {
+	tests := []struct {
+		rawInput     string
+		wantedEvents []*publication
+	}{
+		{
+			rawInput:     "event: eventName\ndata: {\"sample\":\"value\"}\n\n",
+			wantedEvents: []*publication{{event: "eventName", data: "{\"sample\":\"value\"}"}},
+		},
+		{
+			rawInput:     "id: abc\ndata: def\n\n",
+			wantedEvents: []*publication{{id: "abc", lastEventID: "abc", data: "def"}},
+		},
+		{
+			// an event with no data field at all is dropped, per spec
+			rawInput:     "event: noData\n\ndata: abc\n\n",
+			wantedEvents: []*publication{{data: "abc"}},
+		},
+		{
+			// multiple data fields are concatenated with "\n" between them
+			rawInput:     "data: line one\ndata: line two\n\n",
+			wantedEvents: []*publication{{data: "line one\nline two"}},
+		},
+		{
+			// a single empty data field still contributes a (blank) line
+			// to the data buffer, so the event is dispatched with
+			// Data() == "", unlike an event with no data field at all
+			rawInput:     "event: noData\ndata:\n\ndata: abc\n\n",
+			wantedEvents: []*publication{{event: "noData", data: ""}, {data: "abc"}},
+		},
+	}
+
+	for _, test := range tests {
+		var got []*publication
+		parser := NewParser("")
+		parser.OnEvent(func(event Event) {
+			got = append(got, event.(*publication))
+		})
+
+		_, err := io.WriteString(parser, test.rawInput)
+		assert.NoError(t, err, "for input: %q", test.rawInput)
+		assert.Equal(t, test.wantedEvents, got, "for input: %q", test.rawInput)
+	}
+}
+
+func TestParserHandlesWritesSplitAcrossLines(t *testing.T) {
+	var got []*publication
+	parser := NewParser("")
+	parser.OnEvent(func(event Event) {
+		got = append(got, event.(*publication))
+	})
+
+	chunks := []string{"id: ab", "c\nda", "ta: d", "ef\n", "\n"}
+	for _, chunk := range chunks {
+		_, err := io.WriteString(parser, chunk)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []*publication{{id: "abc", lastEventID: "abc", data: "def"}}, got)
+}
+
+func TestParserHandlesCRLFSplitAcrossWrites(t *testing.T) {
+	var got []*publication
+	parser := NewParser("")
+	parser.OnEvent(func(event Event) {
+		got = append(got, event.(*publication))
+	})
+
+	// the "\r" and "\n" of a CRLF line ending arrive in separate Write
+	// calls; the parser must not treat this as two line endings
+	chunks := []string{"data: abc\r", "\n\r", "\n"}
+	for _, chunk := range chunks {
+		_, err := io.WriteString(parser, chunk)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []*publication{{data: "abc"}}, got)
+}
+
+func TestParserStripsBOMSplitAcrossWrites(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+	}{
+		{"whole BOM in first write", []string{"\xEF\xBB\xBFdata: abc\n\n"}},
+		{"BOM split byte by byte", []string{"\xEF", "\xBB", "\xBF", "data: abc\n\n"}},
+		{"BOM split after first two bytes", []string{"\xEF\xBB", "\xBFdata: abc\n\n"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []*publication
+			parser := NewParser("")
+			parser.OnEvent(func(event Event) {
+				got = append(got, event.(*publication))
+			})
+
+			for _, chunk := range test.chunks {
+				_, err := io.WriteString(parser, chunk)
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, []*publication{{data: "abc"}}, got)
+		})
+	}
+}
+
+func TestParserDoesNotStripNonBOMPrefix(t *testing.T) {
+	var got []*publication
+	parser := NewParser("")
+	parser.OnEvent(func(event Event) {
+		got = append(got, event.(*publication))
+	})
+
+	// "\xEF" alone could be the start of a BOM; once followed by
+	// something that isn't the rest of one, it must be treated as
+	// ordinary (if unrecognized) field data rather than silently dropped.
+	chunks := []string{"\xEF", "X\n", "data: abc\n\n"}
+	for _, chunk := range chunks {
+		_, err := io.WriteString(parser, chunk)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []*publication{{data: "abc"}}, got)
+}
+
+func TestParserTracksLastEventIDAndRetry(t *testing.T) {
+	var got []Event
+	parser := NewParser("my-id")
+	parser.OnEvent(func(event Event) {
+		got = append(got, event)
+	})
+
+	_, err := io.WriteString(parser, "retry: 2000\ndata: first\n\nid: abc\ndata: second\n\n")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "my-id", requireLastEventID(t, got[0]))
+	assert.Equal(t, 2000*time.Millisecond, requireRetryMS(t, got[0]))
+
+	assert.Equal(t, "abc", requireLastEventID(t, got[1]))
+	assert.Equal(t, 2000*time.Millisecond, requireRetryMS(t, got[1]))
+}
+
+func TestParserWriteReturnsFullLength(t *testing.T) {
+	parser := NewParser("")
+	input := "data: abc\n\n"
+	n, err := io.WriteString(parser, input)
+	assert.NoError(t, err)
+	assert.Equal(t, len(input), n)
+}
+
+// syntheticStream builds a large event stream of n small events, used by
+// the benchmarks below to compare Parser with Decoder.
+func syntheticStream(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "id: %d\nevent: tick\ndata: {\"n\":%d}\n\n", i, i)
+	}
+	return b.String()
+}
+
+func BenchmarkParser(b *testing.B) {
+	stream := syntheticStream(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		count := 0
+		parser := NewParser("")
+		parser.OnEvent(func(Event) { count++ })
+		io.WriteString(parser, stream)
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	stream := syntheticStream(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decoder := NewDecoder(strings.NewReader(stream))
+		count := 0
+		for {
+			_, err := decoder.Decode()
+			if err != nil {
+				break
+			}
+			count++
+		}
+		decoder.Close()
+	}
+}